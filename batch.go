@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var validFormats = map[string]bool{
+	"plain":            true,
+	"markdown":         true,
+	"org":              true,
+	"hugo-frontmatter": true,
+	"html-card":        true,
+	"json":             true,
+	"csv":              true,
+	"tsv":              true,
+}
+
+func validateFormat(format string) error {
+	if !validFormats[format] {
+		return fmt.Errorf("invalid --format value %q (want plain, markdown, org, hugo-frontmatter, html-card, json, csv, or tsv)", format)
+	}
+	return nil
+}
+
+// batchOnlyFormats are the tabular encodings that only make sense across
+// multiple rows; single-URL mode rejects them instead of silently
+// falling back to a text template for a format it can't actually render.
+var batchOnlyFormats = map[string]bool{
+	"json": true,
+	"csv":  true,
+	"tsv":  true,
+}
+
+// runBatch reads one URL per line from opts.file (or stdin, when
+// opts.file is "-"/unset with a lone "-" positional), fetches their
+// titles concurrently with a bounded worker pool, and writes the
+// results in the requested format while preserving input order.
+func runBatch(opts options, stdin io.Reader, stdout, stderr io.Writer) int {
+	urls, err := readBatchURLs(opts, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if len(urls) == 0 {
+		return 0
+	}
+
+	format := opts.format
+	if format == "" {
+		if opts.markdown {
+			format = "markdown"
+		} else {
+			format = "plain"
+		}
+	}
+
+	cache, err := openDiskCache()
+	if err != nil {
+		cache = nil // proceed without a persistent cache rather than failing the run
+	}
+
+	results := make([]fetchResult, len(urls))
+	sem := make(chan struct{}, opts.concurrency)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, u string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = fetchTitleCached(context.Background(), cache, u, opts.titleSources)
+		}(i, u.url)
+	}
+	wg.Wait()
+
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(stderr, "warning: failed to save title cache: %v\n", err)
+		}
+	}
+
+	hadErr := false
+	for i, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(stderr, "line %d: %s: %v\n", urls[i].line, urls[i].url, r.Err)
+			hadErr = true
+			continue
+		}
+		if opts.history {
+			if err := appendHistory(historyEntry{Title: r.Title, URL: r.URL, Timestamp: time.Now()}); err != nil {
+				fmt.Fprintf(stderr, "warning: failed to record history: %v\n", err)
+			}
+		}
+	}
+
+	if err := writeBatchResults(stdout, format, opts.template, results); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if hadErr {
+		return 1
+	}
+	return 0
+}
+
+// batchURL is one non-blank input line, along with its 1-based line
+// number in the source file so error reporting matches what the user
+// sees in their editor even when blank lines were skipped.
+type batchURL struct {
+	line int
+	url  string
+}
+
+func readBatchURLs(opts options, stdin io.Reader) ([]batchURL, error) {
+	var r io.Reader
+	switch {
+	case opts.file.set && opts.file.value != "-":
+		f, err := os.Open(opts.file.value)
+		if err != nil {
+			return nil, fmt.Errorf("ファイルを開けませんでした: %w", err)
+		}
+		defer f.Close()
+		r = f
+	case opts.file.set, len(opts.positional) == 1 && opts.positional[0] == "-":
+		r = stdin
+	default:
+		return nil, errors.New("バッチモードには -f/--file <path> か \"-\" を指定してください")
+	}
+
+	var urls []batchURL
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		urls = append(urls, batchURL{line: lineNo, url: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("入力の読み取りに失敗しました: %w", err)
+	}
+	return urls, nil
+}
+
+// writeBatchResults writes results in the requested format. json/csv/tsv
+// are tabular encodings handled directly; every other format (plain,
+// markdown, org, hugo-frontmatter, html-card, or a custom --template)
+// renders one entry per successful result through renderResult, each
+// followed by a newline.
+func writeBatchResults(w io.Writer, format, customTemplate string, results []fetchResult) error {
+	switch format {
+	case "json":
+		type row struct {
+			URL   string `json:"url"`
+			Title string `json:"title,omitempty"`
+			Error string `json:"error,omitempty"`
+		}
+		rows := make([]row, 0, len(results))
+		for _, r := range results {
+			rr := row{URL: r.URL, Title: r.Title}
+			if r.Err != nil {
+				rr.Error = r.Err.Error()
+			}
+			rows = append(rows, rr)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "csv", "tsv":
+		cw := csv.NewWriter(w)
+		if format == "tsv" {
+			cw.Comma = '\t'
+		}
+		if err := cw.Write([]string{"url", "title", "error"}); err != nil {
+			return err
+		}
+		for _, r := range results {
+			errText := ""
+			if r.Err != nil {
+				errText = r.Err.Error()
+			}
+			if err := cw.Write([]string{r.URL, r.Title, errText}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tmpl, err := resolveTemplate(format, customTemplate)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				continue
+			}
+			out, err := renderResult(tmpl, r)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, out)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadBatchURLsTracksRealLineNumbers(t *testing.T) {
+	input := "https://example.com/one\n\nhttps://example.com/three\n"
+	opts := options{file: stringFlag{value: "-", set: true}}
+
+	urls, err := readBatchURLs(opts, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readBatchURLs: %v", err)
+	}
+	if len(urls) != 2 {
+		t.Fatalf("got %d urls, want 2", len(urls))
+	}
+	if urls[0].line != 1 || urls[0].url != "https://example.com/one" {
+		t.Errorf("urls[0] = %+v, want line 1 https://example.com/one", urls[0])
+	}
+	if urls[1].line != 3 || urls[1].url != "https://example.com/three" {
+		t.Errorf("urls[1] = %+v, want line 3 https://example.com/three", urls[1])
+	}
+}
+
+func TestReadBatchURLsSkipsBlankLines(t *testing.T) {
+	input := "\n\nhttps://example.com/\n\n"
+	opts := options{file: stringFlag{value: "-", set: true}}
+
+	urls, err := readBatchURLs(opts, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readBatchURLs: %v", err)
+	}
+	if len(urls) != 1 {
+		t.Fatalf("got %d urls, want 1", len(urls))
+	}
+	if urls[0].line != 3 {
+		t.Errorf("urls[0].line = %d, want 3", urls[0].line)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// titleCache is a small in-memory LRU cache with a fixed TTL per entry,
+// used by serve mode so repeated lookups from editors/extensions don't
+// refetch the page every time.
+type titleCache struct {
+	mu      sync.Mutex
+	cap     int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List // front = most recently used
+}
+
+type cacheEntry struct {
+	key       string
+	title     string
+	expiresAt time.Time
+}
+
+func newTitleCache(capacity int, ttl time.Duration) *titleCache {
+	if capacity <= 0 {
+		capacity = defaultCacheCap
+	}
+	return &titleCache{
+		cap:     capacity,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *titleCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return "", false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.title, true
+}
+
+func (c *titleCache) Set(key, title string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).title = title
+		elem.Value.(*cacheEntry).expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	elem := c.order.PushFront(&cacheEntry{key: key, title: title, expiresAt: time.Now().Add(c.ttl)})
+	c.entries[key] = elem
+
+	for c.order.Len() > c.cap {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
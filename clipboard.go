@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"runtime"
+)
+
+// clipboardWriter abstracts a single clipboard backend so it can be
+// swapped out (or faked) independently of the selection logic in
+// copyToClipboard.
+type clipboardWriter interface {
+	Write(text string) error
+}
+
+// clipboardReader abstracts reading the current clipboard contents, the
+// read-side counterpart of clipboardWriter used by watch mode.
+type clipboardReader interface {
+	Read() (string, error)
+}
+
+// readClipboard reads the current clipboard contents, preferring the
+// exec-based backends and falling back to the native library exactly
+// like copyToClipboard does.
+func readClipboard(noNative bool) (string, error) {
+	text, execErr := readWithReaders(execClipboardReaders())
+	if execErr == nil || noNative {
+		return text, execErr
+	}
+	return readWithReaders([]clipboardReader{newNativeClipboardReader()})
+}
+
+func readWithReaders(readers []clipboardReader) (string, error) {
+	if len(readers) == 0 {
+		return "", errors.New("no clipboard backend available")
+	}
+	var lastErr error
+	for _, r := range readers {
+		text, err := r.Read()
+		if err == nil {
+			return text, nil
+		}
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+// execClipboardReader shells out to a platform clipboard-paste command.
+type execClipboardReader struct {
+	name string
+	args []string
+}
+
+func (r execClipboardReader) Read() (string, error) {
+	if _, err := exec.LookPath(r.name); err != nil {
+		return "", err
+	}
+	out, err := exec.Command(r.name, r.args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func execClipboardReaders() []clipboardReader {
+	var candidates []clipboardCmd
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []clipboardCmd{{name: "pbpaste"}}
+	case "windows":
+		candidates = []clipboardCmd{{name: "powershell", args: []string{"-NoProfile", "-Command", "Get-Clipboard"}}}
+	default:
+		candidates = []clipboardCmd{
+			{name: "wl-paste"},
+			{name: "xclip", args: []string{"-selection", "clipboard", "-o"}},
+			{name: "xsel", args: []string{"--clipboard", "--output"}},
+		}
+	}
+
+	readers := make([]clipboardReader, 0, len(candidates))
+	for _, c := range candidates {
+		readers = append(readers, execClipboardReader{name: c.name, args: c.args})
+	}
+	return readers
+}
+
+// copyToClipboard writes text to the system clipboard. It tries the
+// exec-based backends (pbcopy/xclip/xsel/wl-copy/clip) first, since they
+// require no extra Go dependencies, and falls back to the native
+// clipboard library when none of them are available. Passing
+// noNative=true disables the fallback and reproduces the previous
+// exec-only behavior.
+func copyToClipboard(text string, noNative bool) error {
+	execErr := writeWithWriters(text, execClipboardWriters())
+	if execErr == nil || noNative {
+		return execErr
+	}
+	return writeWithWriters(text, []clipboardWriter{newNativeClipboardWriter()})
+}
+
+func writeWithWriters(text string, writers []clipboardWriter) error {
+	if len(writers) == 0 {
+		return errors.New("no clipboard backend available")
+	}
+	var lastErr error
+	for _, w := range writers {
+		err := w.Write(text)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// execClipboardWriter shells out to a platform clipboard command.
+type execClipboardWriter struct {
+	name string
+	args []string
+}
+
+func (w execClipboardWriter) Write(text string) error {
+	if _, err := exec.LookPath(w.name); err != nil {
+		return err
+	}
+	cmd := exec.Command(w.name, w.args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(stdin, text); err != nil {
+		_ = stdin.Close()
+		_ = cmd.Wait()
+		return err
+	}
+	if err := stdin.Close(); err != nil {
+		_ = cmd.Wait()
+		return err
+	}
+	return cmd.Wait()
+}
+
+func execClipboardWriters() []clipboardWriter {
+	var candidates []clipboardCmd
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []clipboardCmd{{name: "pbcopy"}}
+	case "windows":
+		candidates = []clipboardCmd{{name: "clip"}}
+	default:
+		candidates = []clipboardCmd{
+			{name: "wl-copy"},
+			{name: "xclip", args: []string{"-selection", "clipboard"}},
+			{name: "xsel", args: []string{"--clipboard", "--input"}},
+		}
+	}
+
+	writers := make([]clipboardWriter, 0, len(candidates))
+	for _, c := range candidates {
+		writers = append(writers, execClipboardWriter{name: c.name, args: c.args})
+	}
+	return writers
+}
+
+type clipboardCmd struct {
+	name string
+	args []string
+}
@@ -0,0 +1,31 @@
+//go:build !no_native_clipboard
+
+package main
+
+import "github.com/atotto/clipboard"
+
+// nativeClipboardWriter uses the atotto/clipboard library instead of
+// shelling out, so ght keeps working on systems without pbcopy/xclip/
+// xsel/wl-copy/clip installed. Build with -tags no_native_clipboard to
+// drop this dependency entirely.
+type nativeClipboardWriter struct{}
+
+func newNativeClipboardWriter() clipboardWriter {
+	return nativeClipboardWriter{}
+}
+
+func (nativeClipboardWriter) Write(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// nativeClipboardReader is the read-side counterpart of
+// nativeClipboardWriter.
+type nativeClipboardReader struct{}
+
+func newNativeClipboardReader() clipboardReader {
+	return nativeClipboardReader{}
+}
+
+func (nativeClipboardReader) Read() (string, error) {
+	return clipboard.ReadAll()
+}
@@ -0,0 +1,30 @@
+//go:build no_native_clipboard
+
+package main
+
+import "errors"
+
+// nativeClipboardWriter stub used when built with -tags
+// no_native_clipboard, so binaries that must not depend on
+// github.com/atotto/clipboard can still link.
+type nativeClipboardWriter struct{}
+
+func newNativeClipboardWriter() clipboardWriter {
+	return nativeClipboardWriter{}
+}
+
+func (nativeClipboardWriter) Write(string) error {
+	return errors.New("native clipboard support was disabled at build time")
+}
+
+// nativeClipboardReader is the read-side counterpart of
+// nativeClipboardWriter.
+type nativeClipboardReader struct{}
+
+func newNativeClipboardReader() clipboardReader {
+	return nativeClipboardReader{}
+}
+
+func (nativeClipboardReader) Read() (string, error) {
+	return "", errors.New("native clipboard support was disabled at build time")
+}
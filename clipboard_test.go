@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeClipboardWriter lets tests exercise copyToClipboard's fallback
+// logic without shelling out to a real clipboard backend.
+type fakeClipboardWriter struct {
+	err      error
+	got      string
+	writeErr func(text string) error
+}
+
+func (w *fakeClipboardWriter) Write(text string) error {
+	w.got = text
+	if w.writeErr != nil {
+		return w.writeErr(text)
+	}
+	return w.err
+}
+
+type fakeClipboardReader struct {
+	text string
+	err  error
+}
+
+func (r fakeClipboardReader) Read() (string, error) {
+	return r.text, r.err
+}
+
+func TestWriteWithWritersUsesFirstSuccess(t *testing.T) {
+	first := &fakeClipboardWriter{err: errors.New("no display")}
+	second := &fakeClipboardWriter{}
+	third := &fakeClipboardWriter{}
+
+	if err := writeWithWriters("hello", []clipboardWriter{first, second, third}); err != nil {
+		t.Fatalf("writeWithWriters: %v", err)
+	}
+	if second.got != "hello" {
+		t.Errorf("second writer got %q, want %q", second.got, "hello")
+	}
+	if third.got != "" {
+		t.Errorf("third writer should not have been called, got %q", third.got)
+	}
+}
+
+func TestWriteWithWritersAllFail(t *testing.T) {
+	wantErr := errors.New("last backend failed")
+	writers := []clipboardWriter{
+		&fakeClipboardWriter{err: errors.New("first backend failed")},
+		&fakeClipboardWriter{err: wantErr},
+	}
+
+	err := writeWithWriters("hello", writers)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("writeWithWriters error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestWriteWithWritersNoBackends(t *testing.T) {
+	if err := writeWithWriters("hello", nil); err == nil {
+		t.Error("writeWithWriters with no backends should return an error")
+	}
+}
+
+func TestReadWithReadersUsesFirstSuccess(t *testing.T) {
+	readers := []clipboardReader{
+		fakeClipboardReader{err: errors.New("no display")},
+		fakeClipboardReader{text: "https://example.com"},
+	}
+
+	text, err := readWithReaders(readers)
+	if err != nil {
+		t.Fatalf("readWithReaders: %v", err)
+	}
+	if text != "https://example.com" {
+		t.Errorf("readWithReaders = %q, want %q", text, "https://example.com")
+	}
+}
+
+func TestReadWithReadersAllFail(t *testing.T) {
+	wantErr := errors.New("clipboard empty")
+	readers := []clipboardReader{fakeClipboardReader{err: wantErr}}
+
+	_, err := readWithReaders(readers)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("readWithReaders error = %v, want %v", err, wantErr)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheRecord is one entry of the on-disk title cache, keyed by
+// canonicalized URL. It mirrors fetchResult's metadata fields so a 304
+// response can be served entirely from the cache without losing any of
+// it.
+type cacheRecord struct {
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	SiteName     string    `json:"site_name,omitempty"`
+	Author       string    `json:"author,omitempty"`
+	PublishedAt  string    `json:"published_at,omitempty"`
+	Image        string    `json:"image,omitempty"`
+	Favicon      string    `json:"favicon,omitempty"`
+	Lang         string    `json:"lang,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+}
+
+// diskCache is the persistent counterpart of the in-memory titleCache
+// used by serve mode: it survives between CLI invocations at
+// $XDG_CACHE_HOME/ght/titles.json, letting fetchTitleConditional send
+// If-None-Match/If-Modified-Since and skip reparsing on a 304.
+type diskCache struct {
+	path    string
+	mu      sync.Mutex
+	records map[string]cacheRecord
+}
+
+func diskCachePath() (string, error) {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(dir, "ght", "titles.json"), nil
+}
+
+// openDiskCache loads the on-disk cache, starting empty if it doesn't
+// exist yet or can't be parsed.
+func openDiskCache() (*diskCache, error) {
+	path, err := diskCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	dc := &diskCache{path: path, records: map[string]cacheRecord{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dc, nil
+		}
+		return nil, err
+	}
+	_ = json.Unmarshal(data, &dc.records) // a corrupt cache file just starts fresh
+	return dc, nil
+}
+
+func (c *diskCache) Get(canonicalURL string) (cacheRecord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rec, ok := c.records[canonicalURL]
+	return rec, ok
+}
+
+func (c *diskCache) Set(canonicalURL string, rec cacheRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records[canonicalURL] = rec
+}
+
+// Save persists the cache to disk, creating its parent directory if
+// needed.
+func (c *diskCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o644)
+}
+
+// trackingQueryPrefixes and trackingQueryNames list query parameters
+// stripped before a URL is used as a cache key, so e.g. the same article
+// shared with different utm_* campaigns still hits the same entry.
+var trackingQueryPrefixes = []string{"utm_"}
+
+var trackingQueryNames = map[string]bool{
+	"fbclid": true,
+	"gclid":  true,
+}
+
+// canonicalizeURL strips known tracking query parameters so that
+// differently-tagged links to the same page share one cache entry.
+func canonicalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	q := u.Query()
+	for key := range q {
+		lower := strings.ToLower(key)
+		if trackingQueryNames[lower] {
+			q.Del(key)
+			continue
+		}
+		for _, prefix := range trackingQueryPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				q.Del(key)
+				break
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	encoded := url.Values{}
+	for _, k := range keys {
+		encoded[k] = q[k]
+	}
+	u.RawQuery = encoded.Encode()
+
+	return u.String()
+}
+
+// fetchTitleCached wraps fetchTitle with the on-disk cache: it sends
+// the stored ETag/Last-Modified as conditional headers and, on a 304,
+// returns the cached title without re-parsing the page. cache may be
+// nil, in which case this behaves exactly like fetchTitle.
+func fetchTitleCached(ctx context.Context, cache *diskCache, rawURL string, sources []string) fetchResult {
+	if cache == nil {
+		return fetchTitle(ctx, rawURL, sources)
+	}
+
+	canon := canonicalizeURL(normalizeURL(rawURL))
+	rec, hasRec := cache.Get(canon)
+
+	etag, lastModified := "", ""
+	if hasRec {
+		etag, lastModified = rec.ETag, rec.LastModified
+	}
+
+	result, meta := fetchTitleConditional(ctx, rawURL, sources, etag, lastModified)
+	if meta.NotModified {
+		return fetchResult{
+			URL:         result.URL,
+			FinalURL:    result.FinalURL,
+			Title:       rec.Title,
+			Description: rec.Description,
+			SiteName:    rec.SiteName,
+			Author:      rec.Author,
+			PublishedAt: rec.PublishedAt,
+			Image:       rec.Image,
+			Favicon:     rec.Favicon,
+			Lang:        rec.Lang,
+		}
+	}
+	if result.Err == nil {
+		cache.Set(canon, cacheRecord{
+			Title:        result.Title,
+			Description:  result.Description,
+			SiteName:     result.SiteName,
+			Author:       result.Author,
+			PublishedAt:  result.PublishedAt,
+			Image:        result.Image,
+			Favicon:      result.Favicon,
+			Lang:         result.Lang,
+			FetchedAt:    time.Now(),
+			ETag:         meta.ETag,
+			LastModified: meta.LastModified,
+		})
+	}
+	return result
+}
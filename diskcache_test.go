@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestCanonicalizeURLStripsTrackingParams(t *testing.T) {
+	got := canonicalizeURL("https://example.com/article?utm_source=feed&utm_medium=rss&id=42&fbclid=abc")
+	want := canonicalizeURL("https://example.com/article?id=42")
+	if got != want {
+		t.Errorf("canonicalizeURL with tracking params = %q, want %q", got, want)
+	}
+}
+
+func TestCanonicalizeURLAppliedToNormalizedForm(t *testing.T) {
+	// A scheme-less URL and its https:// equivalent must land under the
+	// same cache key, since fetchTitleConditional normalizes the former
+	// to the latter before ever making the request.
+	schemeLess := canonicalizeURL(normalizeURL("example.com/article"))
+	schemed := canonicalizeURL(normalizeURL("https://example.com/article"))
+	if schemeLess != schemed {
+		t.Errorf("canonicalizeURL(normalizeURL(...)) mismatch: %q vs %q", schemeLess, schemed)
+	}
+}
+
+func TestDiskCacheGetSetRoundTrip(t *testing.T) {
+	c := &diskCache{records: map[string]cacheRecord{}}
+	c.Set("https://example.com/", cacheRecord{Title: "Example"})
+
+	rec, ok := c.Get("https://example.com/")
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	if rec.Title != "Example" {
+		t.Errorf("Title = %q, want %q", rec.Title, "Example")
+	}
+
+	if _, ok := c.Get("https://example.com/other"); ok {
+		t.Error("expected cache miss for an unset key")
+	}
+}
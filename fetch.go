@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const fetchTimeout = 15 * time.Second
+
+// maxBodyBytes bounds how much of the response we'll ever read, as a
+// safety net for pages whose requested title sources never appear.
+const maxBodyBytes = 5 << 20
+
+// defaultTitleSources is the fallback order used when --title-source is
+// not given: the <title> tag, then OpenGraph, then Twitter Card, then
+// the first <h1>.
+var defaultTitleSources = []string{"title", "og", "twitter", "h1"}
+
+var validTitleSources = map[string]bool{
+	"title":   true,
+	"og":      true,
+	"twitter": true,
+	"h1":      true,
+}
+
+// parseTitleSources parses a comma-separated --title-source value like
+// "og,twitter,title" into its component source names, validating each.
+func parseTitleSources(raw string) ([]string, error) {
+	parts := strings.Split(raw, ",")
+	sources := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !validTitleSources[p] {
+			return nil, fmt.Errorf("invalid --title-source value %q (want title, og, twitter, or h1)", p)
+		}
+		sources = append(sources, p)
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("--title-source requires at least one source")
+	}
+	return sources, nil
+}
+
+// fetchResult is the outcome of a single fetchTitle call, and also the
+// data a --template/--format expansion renders against. Batch mode
+// collects these (one per input URL) so it can preserve input order and
+// report per-line failures without aborting the whole run.
+type fetchResult struct {
+	URL         string
+	FinalURL    string
+	Title       string
+	Description string
+	SiteName    string
+	Author      string
+	PublishedAt string
+	Image       string
+	Favicon     string
+	Lang        string
+	Err         error
+}
+
+// fetchTitle fetches rawURL and extracts its title according to
+// sources, the priority order of title/og/twitter/h1 to try.
+func fetchTitle(ctx context.Context, rawURL string, sources []string) fetchResult {
+	result, _ := fetchTitleConditional(ctx, rawURL, sources, "", "")
+	return result
+}
+
+// normalizeURL applies the same scheme-less-URL default that
+// fetchTitleConditional fetches with, so callers that derive a cache key
+// from a user-supplied URL key it under the same form it's actually
+// fetched under.
+func normalizeURL(rawURL string) string {
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return "https://" + rawURL
+	}
+	return rawURL
+}
+
+// fetchMeta carries the response metadata fetchTitleConditional needs
+// to report back to a caller maintaining its own HTTP cache.
+type fetchMeta struct {
+	NotModified  bool
+	ETag         string
+	LastModified string
+}
+
+// fetchTitleConditional is fetchTitle plus conditional-GET support: when
+// etag/lastModified are non-empty they're sent as If-None-Match/
+// If-Modified-Since, and a 304 response short-circuits straight to
+// fetchMeta.NotModified without any parsing.
+func fetchTitleConditional(ctx context.Context, rawURL string, sources []string, etag, lastModified string) (fetchResult, fetchMeta) {
+	normalized := normalizeURL(rawURL)
+
+	result := fetchResult{URL: normalized}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, normalized, nil)
+	if err != nil {
+		result.Err = fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+		return result, fetchMeta{}
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Err = fmt.Errorf("URLの取得に失敗しました: %w", err)
+		return result, fetchMeta{}
+	}
+	defer resp.Body.Close()
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		result.FinalURL = resp.Request.URL.String()
+	} else {
+		result.FinalURL = normalized
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return result, fetchMeta{NotModified: true, ETag: etag, LastModified: lastModified}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Err = fmt.Errorf("HTTPエラー: %s", resp.Status)
+		return result, fetchMeta{}
+	}
+
+	utf8Reader, err := charset.NewReader(io.LimitReader(resp.Body, maxBodyBytes), resp.Header.Get("Content-Type"))
+	if err != nil {
+		result.Err = fmt.Errorf("文字コードの判定に失敗しました: %w", err)
+		return result, fetchMeta{}
+	}
+
+	meta, err := extractMetadata(utf8Reader, result.FinalURL, sources)
+	if err != nil {
+		result.Err = err
+		return result, fetchMeta{}
+	}
+
+	result.Title = meta.Title
+	result.Description = meta.Description
+	result.SiteName = meta.SiteName
+	result.Author = meta.Author
+	result.PublishedAt = meta.PublishedAt
+	result.Image = meta.Image
+	result.Favicon = meta.Favicon
+	result.Lang = meta.Lang
+
+	return result, fetchMeta{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+}
+
+// pageMetadata holds everything extractMetadata can pull out of a page
+// besides the title itself.
+type pageMetadata struct {
+	Title       string
+	Description string
+	SiteName    string
+	Author      string
+	PublishedAt string
+	Image       string
+	Favicon     string
+	Lang        string
+}
+
+// extractMetadata streams r through an HTML tokenizer, collecting the
+// title (per the --title-source priority in sources) alongside
+// OpenGraph/Twitter/Dublin Core meta tags, <link rel="icon">, and the
+// document language. Fields that never appear in the document are left
+// as "".
+//
+// The head is always read in full so that metadata appearing after the
+// title resolves is never missed. Once the head ends, parsing stops
+// there unless the title is still unresolved and "h1" is among sources,
+// in which case the body is scanned for the first <h1> as well.
+func extractMetadata(r io.Reader, baseURL string, sources []string) (pageMetadata, error) {
+	z := html.NewTokenizer(r)
+	candidates := map[string]string{}
+	meta := pageMetadata{}
+	inBody := false
+	headDone := false
+	captureTarget := ""
+	var buf strings.Builder
+
+	base, _ := url.Parse(baseURL)
+
+	resolveTitle := func() (string, bool) {
+		for _, s := range sources {
+			if v, ok := candidates[s]; ok && v != "" {
+				return v, true
+			}
+		}
+		return "", false
+	}
+
+	needsH1 := func() bool {
+		for _, s := range sources {
+			if s == "h1" {
+				return true
+			}
+		}
+		return false
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		switch tt {
+		case html.TextToken:
+			if captureTarget != "" {
+				buf.WriteString(z.Token().Data)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch strings.ToLower(tok.Data) {
+			case "html":
+				for _, a := range tok.Attr {
+					if strings.ToLower(a.Key) == "lang" && meta.Lang == "" {
+						meta.Lang = a.Val
+					}
+				}
+			case "body":
+				inBody = true
+				// Some hand-rolled/minified pages never emit a literal
+				// </head>; a <body> start tag marks the head's end just
+				// as reliably, so don't wait for a tag that may never
+				// come.
+				headDone = true
+			case "title":
+				if _, ok := candidates["title"]; !ok && !inBody {
+					captureTarget = "title"
+					buf.Reset()
+				}
+			case "h1":
+				if _, ok := candidates["h1"]; !ok && inBody {
+					captureTarget = "h1"
+					buf.Reset()
+				}
+			case "link":
+				applyLinkTag(tok, base, &meta)
+			case "meta":
+				name, content := metaNameContent(tok)
+				if content == "" {
+					break
+				}
+				applyMetaTag(name, content, candidates, &meta)
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			switch strings.ToLower(tok.Data) {
+			case "title":
+				if captureTarget == "title" {
+					candidates["title"] = cleanTitleText(buf.String())
+					captureTarget = ""
+				}
+			case "h1":
+				if captureTarget == "h1" {
+					candidates["h1"] = cleanTitleText(buf.String())
+					captureTarget = ""
+				}
+			case "head":
+				headDone = true
+			}
+		}
+
+		if headDone {
+			if _, resolved := resolveTitle(); resolved || !needsH1() {
+				break
+			}
+			if _, ok := candidates["h1"]; ok {
+				break
+			}
+		}
+	}
+
+	title, ok := resolveTitle()
+	if !ok {
+		return pageMetadata{}, errors.New("titleが見つかりませんでした")
+	}
+	meta.Title = title
+	return meta, nil
+}
+
+// applyMetaTag records a <meta> tag's content under the appropriate
+// title candidate and/or pageMetadata field. og:description,
+// og:site_name and article:published_time overwrite any earlier value
+// of their field; every other tag keeps the first-encountered value.
+func applyMetaTag(name, content string, candidates map[string]string, meta *pageMetadata) {
+	switch name {
+	case "og:title":
+		if _, ok := candidates["og"]; !ok {
+			candidates["og"] = cleanTitleText(content)
+		}
+	case "twitter:title":
+		if _, ok := candidates["twitter"]; !ok {
+			candidates["twitter"] = cleanTitleText(content)
+		}
+	case "og:description":
+		meta.Description = content
+	case "twitter:description", "description":
+		if meta.Description == "" {
+			meta.Description = content
+		}
+	case "og:site_name":
+		meta.SiteName = content
+	case "author", "dc.creator", "dcterms.creator":
+		if meta.Author == "" {
+			meta.Author = content
+		}
+	case "article:published_time":
+		meta.PublishedAt = content
+	case "date", "dc.date", "dcterms.date", "dcterms.issued":
+		if meta.PublishedAt == "" {
+			meta.PublishedAt = content
+		}
+	case "og:image", "twitter:image", "twitter:image:src":
+		if meta.Image == "" {
+			meta.Image = content
+		}
+	}
+}
+
+// applyLinkTag records a page icon from <link rel="icon"> or
+// rel="shortcut icon">, resolving it against base if it's relative.
+func applyLinkTag(tok html.Token, base *url.URL, meta *pageMetadata) {
+	if meta.Favicon != "" {
+		return
+	}
+	var rel, href string
+	for _, a := range tok.Attr {
+		switch strings.ToLower(a.Key) {
+		case "rel":
+			rel = strings.ToLower(a.Val)
+		case "href":
+			href = a.Val
+		}
+	}
+	if href == "" {
+		return
+	}
+	switch rel {
+	case "icon", "shortcut icon", "apple-touch-icon":
+	default:
+		return
+	}
+	if base == nil {
+		meta.Favicon = href
+		return
+	}
+	if ref, err := url.Parse(href); err == nil {
+		meta.Favicon = base.ResolveReference(ref).String()
+		return
+	}
+	meta.Favicon = href
+}
+
+func metaNameContent(tok html.Token) (name, content string) {
+	for _, a := range tok.Attr {
+		switch strings.ToLower(a.Key) {
+		case "property":
+			if name == "" {
+				name = strings.ToLower(a.Val)
+			}
+		case "name":
+			if name == "" {
+				name = strings.ToLower(a.Val)
+			}
+		case "content":
+			content = a.Val
+		}
+	}
+	return name, content
+}
+
+func cleanTitleText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractMetadataTitlePriority(t *testing.T) {
+	html := `<!doctype html>
+<html lang="en">
+<head>
+<title>Title Tag</title>
+<meta property="og:title" content="OG Title">
+<meta property="og:description" content="A description.">
+<meta property="og:site_name" content="Example Site">
+<meta name="author" content="Jane Doe">
+<meta property="article:published_time" content="2026-01-01T00:00:00Z">
+<meta property="og:image" content="/cover.png">
+<link rel="icon" href="/favicon.ico">
+</head>
+<body>
+<h1>H1 Heading</h1>
+</body>
+</html>`
+
+	meta, err := extractMetadata(strings.NewReader(html), "https://example.com/page", defaultTitleSources)
+	if err != nil {
+		t.Fatalf("extractMetadata: %v", err)
+	}
+	if meta.Title != "Title Tag" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Title Tag")
+	}
+	if meta.Description != "A description." {
+		t.Errorf("Description = %q, want %q", meta.Description, "A description.")
+	}
+	if meta.SiteName != "Example Site" {
+		t.Errorf("SiteName = %q, want %q", meta.SiteName, "Example Site")
+	}
+	if meta.Author != "Jane Doe" {
+		t.Errorf("Author = %q, want %q", meta.Author, "Jane Doe")
+	}
+	if meta.PublishedAt != "2026-01-01T00:00:00Z" {
+		t.Errorf("PublishedAt = %q, want %q", meta.PublishedAt, "2026-01-01T00:00:00Z")
+	}
+	if meta.Image != "/cover.png" {
+		t.Errorf("Image = %q, want %q", meta.Image, "/cover.png")
+	}
+	if meta.Favicon != "https://example.com/favicon.ico" {
+		t.Errorf("Favicon = %q, want %q", meta.Favicon, "https://example.com/favicon.ico")
+	}
+	if meta.Lang != "en" {
+		t.Errorf("Lang = %q, want %q", meta.Lang, "en")
+	}
+}
+
+func TestExtractMetadataFallsBackToH1WhenTitleMissing(t *testing.T) {
+	html := `<!doctype html>
+<html>
+<head></head>
+<body>
+<h1>Body Heading</h1>
+</body>
+</html>`
+
+	meta, err := extractMetadata(strings.NewReader(html), "https://example.com/", defaultTitleSources)
+	if err != nil {
+		t.Fatalf("extractMetadata: %v", err)
+	}
+	if meta.Title != "Body Heading" {
+		t.Errorf("Title = %q, want %q", meta.Title, "Body Heading")
+	}
+}
+
+func TestExtractMetadataNoHeadCloseTag(t *testing.T) {
+	// Some hand-rolled pages never emit a literal </head>; the parser
+	// must still stop reading once <body> starts rather than scanning to
+	// maxBodyBytes or EOF.
+	html := `<html><head><title>No Closing Head</title><body><h1>Ignored</h1></body></html>`
+
+	meta, err := extractMetadata(strings.NewReader(html), "https://example.com/", []string{"title"})
+	if err != nil {
+		t.Fatalf("extractMetadata: %v", err)
+	}
+	if meta.Title != "No Closing Head" {
+		t.Errorf("Title = %q, want %q", meta.Title, "No Closing Head")
+	}
+}
+
+func TestExtractMetadataNoTitleSourceFound(t *testing.T) {
+	html := `<html><head></head><body><p>no title here</p></body></html>`
+
+	if _, err := extractMetadata(strings.NewReader(html), "https://example.com/", defaultTitleSources); err == nil {
+		t.Error("extractMetadata should return an error when no source resolves")
+	}
+}
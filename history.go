@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// historyEntry is one successful lookup recorded by --history, turning
+// ght into a lightweight read-later capture tool.
+type historyEntry struct {
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func historyFilePath() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "ght", "history.jsonl"), nil
+}
+
+// appendHistory records one lookup to the bookmarks file, creating it
+// (and its parent directory) on first use.
+func appendHistory(entry historyEntry) error {
+	path, err := historyFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(entry)
+}
+
+func readHistory() ([]historyEntry, error) {
+	path, err := historyFilePath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e historyEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("履歴ファイルの読み取りに失敗しました: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// runHistory implements the `ght history` subcommand: list, search, and
+// export.
+func runHistory(args []string, stdout, stderr io.Writer) int {
+	entries, err := readHistory()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	sub := ""
+	rest := args
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		sub = args[0]
+		rest = args[1:]
+	}
+
+	switch sub {
+	case "", "list":
+		for _, e := range entries {
+			fmt.Fprintf(stdout, "%s\t%s\t%s\n", e.Timestamp.Format(time.RFC3339), e.Title, e.URL)
+		}
+	case "search":
+		if len(rest) == 0 {
+			fmt.Fprintln(stderr, errors.New("ght history search <query>"))
+			return 2
+		}
+		query := strings.ToLower(strings.Join(rest, " "))
+		for _, e := range entries {
+			if strings.Contains(strings.ToLower(e.Title), query) || strings.Contains(strings.ToLower(e.URL), query) {
+				fmt.Fprintf(stdout, "%s\t%s\t%s\n", e.Timestamp.Format(time.RFC3339), e.Title, e.URL)
+			}
+		}
+	case "export":
+		for _, e := range entries {
+			fmt.Fprintf(stdout, "- [%s](%s) (%s)\n", e.Title, e.URL, e.Timestamp.Format(time.RFC3339))
+		}
+	default:
+		fmt.Fprintf(stderr, "unknown history subcommand %q (want list, search, or export)\n", sub)
+		return 2
+	}
+
+	return 0
+}
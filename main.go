@@ -1,77 +1,191 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"html"
 	"io"
-	"net/http"
 	"os"
-	"os/exec"
 	"regexp"
-	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const usageText = `usage: ght [-h|--help] [-u|--url "<value>"] [-m|--markdown] [-c|--copy]
+            [--no-clipboard-native]
+            [-w|--watch] [--watch-interval <duration>] [--watch-pattern <regexp>]
+            [-f|--file <path>|-] [--concurrency <n>]
+            [--format <plain|markdown|org|hugo-frontmatter|html-card|json|csv|tsv>]
+            [--template <text/template>]
+       ght serve [--listen <host:port>] [--port <n>] [--cors-origin <origin,...>]
 
            Get HTML Title
 
 Arguments:
 
-  -h  --help      ヘルプ情報を表示
-  -u  --url       取得するURLを指定
-  -m  --markdown  Markdown形式で出力
-  -c  --copy      クリップボードにコピー
+  -h  --help               ヘルプ情報を表示
+  -u  --url                取得するURLを指定
+  -m  --markdown           Markdown形式で出力
+  -c  --copy               クリップボードにコピー
+      --no-clipboard-native ネイティブクリップボードを使わず外部コマンドのみ使用
+  -w  --watch              クリップボードを監視し、URLをタイトルに置き換える
+      --watch-interval     監視間隔 (例: 500ms, 2s) (デフォルト: 1s)
+      --watch-pattern      置き換え対象URLを絞り込む正規表現
+  -f  --file               URLを1行ずつ読み込むファイル ("-" でstdin)
+      --concurrency        バッチモードの同時実行数 (デフォルト: 8)
+      --format             出力形式 (plain/markdown/org/hugo-frontmatter/html-card/json/csv/tsv)
+      --template           出力テンプレート (Go text/template形式、json/csv/tsv以外の--formatより優先)
+                            参照可能なフィールド: .URL .FinalURL .Title .Description
+                            .SiteName .Author .PublishedAt .Image .Favicon .Lang
+      --title-source       タイトル抽出元の優先順位 (カンマ区切り: title,og,twitter,h1)
+      --history            検索結果を履歴ファイルに追記
+
+Subcommands:
+
+  serve    GET /title, POST /titles を提供するローカルHTTPサーバを起動
+             --listen       待受アドレス (デフォルト: 127.0.0.1:4000)
+             --port         待受ポートのみを上書き
+             --cors-origin  CORSを許可するOriginのカンマ区切りリスト
+  history  --history で記録した履歴を表示・検索・エクスポート
+             history [list]        履歴を一覧表示
+             history search <word> タイトル/URLを検索
+             history export        Markdown形式で出力
 `
 
-var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+const defaultWatchInterval = 1 * time.Second
+
+var defaultWatchPattern = regexp.MustCompile(`(?i)^https?://\S+$`)
 
 type stringFlag struct {
 	value string
 	set   bool
 }
 
+// options holds every parsed command-line flag. A struct keeps run() and
+// parseArgs() readable as more flags are added across one-shot,
+// watch, and batch modes (serve mode parses its own serveOptions).
+type options struct {
+	help              bool
+	markdown          bool
+	copyOut           bool
+	noClipboardNative bool
+	watch             bool
+	watchInterval     time.Duration
+	watchPattern      *regexp.Regexp
+	urlArg            stringFlag
+	positional        []string
+	file              stringFlag
+	concurrency       int
+	format            string
+	template          string
+	titleSources      []string
+	history           bool
+}
+
+const defaultConcurrency = 8
+
+// isBatch reports whether the parsed options request batch mode: either
+// -f/--file was given, or the sole positional argument is "-" (stdin)
+// and -u/--url was not given.
+func (o options) isBatch() bool {
+	if o.file.set {
+		return true
+	}
+	return len(o.positional) == 1 && o.positional[0] == "-" && !o.urlArg.set
+}
+
 func main() {
-	exitCode := run(os.Args[1:], os.Stdout, os.Stderr)
+	exitCode := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
 	os.Exit(exitCode)
 }
 
-func run(args []string, stdout, stderr io.Writer) int {
-	help, markdown, copyOut, urlArg, positional, err := parseArgs(args)
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "serve" {
+		return runServe(args[1:], stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "history" {
+		return runHistory(args[1:], stdout, stderr)
+	}
+
+	opts, err := parseArgs(args)
 	if err != nil {
+		fmt.Fprintln(stderr, err)
 		fmt.Fprint(stderr, usageText)
 		return 2
 	}
 
-	if help {
+	if opts.help {
 		fmt.Fprint(stdout, usageText)
 		return 0
 	}
 
-	url, err := resolveURL(positional, urlArg)
+	if opts.watch {
+		return runWatch(opts, stdout, stderr)
+	}
+
+	if opts.isBatch() {
+		return runBatch(opts, stdin, stdout, stderr)
+	}
+
+	if batchOnlyFormats[opts.format] {
+		fmt.Fprintf(stderr, "--format %s produces one row per URL and requires batch mode (-f/--file or \"-\"); a single lookup only supports plain, markdown, org, hugo-frontmatter, or html-card\n", opts.format)
+		return 2
+	}
+
+	url, err := resolveURL(opts.positional, opts.urlArg)
 	if err != nil {
 		fmt.Fprintln(stderr, err)
 		fmt.Fprint(stderr, usageText)
 		return 2
 	}
 
-	title, err := fetchTitle(url)
+	cache, err := openDiskCache()
 	if err != nil {
-		fmt.Fprintln(stderr, err)
+		cache = nil // proceed without a persistent cache rather than failing the lookup
+	}
+
+	result := fetchTitleCached(context.Background(), cache, url, opts.titleSources)
+	if result.Err != nil {
+		fmt.Fprintln(stderr, result.Err)
 		return 1
 	}
 
-	output := title
-	if markdown {
-		output = fmt.Sprintf("[%s](%s)", title, url)
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(stderr, "warning: failed to save title cache: %v\n", err)
+		}
+	}
+
+	if opts.history {
+		if err := appendHistory(historyEntry{Title: result.Title, URL: result.URL, Timestamp: time.Now()}); err != nil {
+			fmt.Fprintf(stderr, "warning: failed to record history: %v\n", err)
+		}
+	}
+
+	format := opts.format
+	if format == "" {
+		if opts.markdown {
+			format = "markdown"
+		} else {
+			format = "plain"
+		}
+	}
+	tmpl, err := resolveTemplate(format, opts.template)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	output, err := renderResult(tmpl, result)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
 	}
 
 	fmt.Fprintln(stdout, output)
 
-	if copyOut {
-		if err := copyToClipboard(output); err != nil {
+	if opts.copyOut {
+		if err := copyToClipboard(output, opts.noClipboardNative); err != nil {
 			fmt.Fprintf(stderr, "clipboard copy failed: %v\n", err)
 			return 1
 		}
@@ -80,60 +194,168 @@ func run(args []string, stdout, stderr io.Writer) int {
 	return 0
 }
 
-func parseArgs(args []string) (bool, bool, bool, stringFlag, []string, error) {
-	var (
-		help       bool
-		markdown   bool
-		copyOut    bool
-		urlArg     stringFlag
-		positional []string
-	)
+func parseArgs(args []string) (options, error) {
+	opts := options{
+		watchInterval: defaultWatchInterval,
+		watchPattern:  defaultWatchPattern,
+		concurrency:   defaultConcurrency,
+		titleSources:  defaultTitleSources,
+	}
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		switch {
 		case arg == "-h" || arg == "--help":
-			help = true
+			opts.help = true
 		case arg == "-m" || arg == "--markdown":
-			markdown = true
+			opts.markdown = true
 		case arg == "-c" || arg == "--copy":
-			copyOut = true
+			opts.copyOut = true
+		case arg == "--no-clipboard-native":
+			opts.noClipboardNative = true
+		case arg == "-w" || arg == "--watch":
+			opts.watch = true
+		case arg == "--watch-interval":
+			if i+1 >= len(args) {
+				return options{}, errors.New("missing --watch-interval value")
+			}
+			d, err := time.ParseDuration(args[i+1])
+			if err != nil {
+				return options{}, fmt.Errorf("invalid --watch-interval value: %w", err)
+			}
+			opts.watchInterval = d
+			i++
+		case strings.HasPrefix(arg, "--watch-interval="):
+			d, err := time.ParseDuration(strings.TrimPrefix(arg, "--watch-interval="))
+			if err != nil {
+				return options{}, fmt.Errorf("invalid --watch-interval value: %w", err)
+			}
+			opts.watchInterval = d
+		case arg == "--watch-pattern":
+			if i+1 >= len(args) {
+				return options{}, errors.New("missing --watch-pattern value")
+			}
+			re, err := regexp.Compile(args[i+1])
+			if err != nil {
+				return options{}, fmt.Errorf("invalid --watch-pattern value: %w", err)
+			}
+			opts.watchPattern = re
+			i++
+		case strings.HasPrefix(arg, "--watch-pattern="):
+			re, err := regexp.Compile(strings.TrimPrefix(arg, "--watch-pattern="))
+			if err != nil {
+				return options{}, fmt.Errorf("invalid --watch-pattern value: %w", err)
+			}
+			opts.watchPattern = re
+		case arg == "-f" || arg == "--file":
+			if i+1 >= len(args) {
+				return options{}, errors.New("missing --file value")
+			}
+			opts.file = stringFlag{value: args[i+1], set: true}
+			i++
+		case strings.HasPrefix(arg, "--file="):
+			opts.file = stringFlag{value: strings.TrimPrefix(arg, "--file="), set: true}
+		case arg == "--concurrency":
+			if i+1 >= len(args) {
+				return options{}, errors.New("missing --concurrency value")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return options{}, errors.New("invalid --concurrency value")
+			}
+			opts.concurrency = n
+			i++
+		case strings.HasPrefix(arg, "--concurrency="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--concurrency="))
+			if err != nil || n < 1 {
+				return options{}, errors.New("invalid --concurrency value")
+			}
+			opts.concurrency = n
+		case arg == "--format":
+			if i+1 >= len(args) {
+				return options{}, errors.New("missing --format value")
+			}
+			if err := validateFormat(args[i+1]); err != nil {
+				return options{}, err
+			}
+			opts.format = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--format="):
+			f := strings.TrimPrefix(arg, "--format=")
+			if err := validateFormat(f); err != nil {
+				return options{}, err
+			}
+			opts.format = f
+		case arg == "--template":
+			if i+1 >= len(args) {
+				return options{}, errors.New("missing --template value")
+			}
+			opts.template = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--template="):
+			opts.template = strings.TrimPrefix(arg, "--template=")
+		case arg == "--history":
+			opts.history = true
+		case arg == "--title-source":
+			if i+1 >= len(args) {
+				return options{}, errors.New("missing --title-source value")
+			}
+			sources, err := parseTitleSources(args[i+1])
+			if err != nil {
+				return options{}, err
+			}
+			opts.titleSources = sources
+			i++
+		case strings.HasPrefix(arg, "--title-source="):
+			sources, err := parseTitleSources(strings.TrimPrefix(arg, "--title-source="))
+			if err != nil {
+				return options{}, err
+			}
+			opts.titleSources = sources
 		case arg == "-u" || arg == "--url":
 			if i+1 >= len(args) {
-				return false, false, false, stringFlag{}, nil, errors.New("missing URL value")
+				return options{}, errors.New("missing URL value")
 			}
-			urlArg = stringFlag{value: args[i+1], set: true}
+			opts.urlArg = stringFlag{value: args[i+1], set: true}
 			i++
 		case strings.HasPrefix(arg, "--url="):
-			urlArg = stringFlag{value: strings.TrimPrefix(arg, "--url="), set: true}
+			opts.urlArg = stringFlag{value: strings.TrimPrefix(arg, "--url="), set: true}
 		case strings.HasPrefix(arg, "-u="):
-			urlArg = stringFlag{value: strings.TrimPrefix(arg, "-u="), set: true}
+			opts.urlArg = stringFlag{value: strings.TrimPrefix(arg, "-u="), set: true}
 		case strings.HasPrefix(arg, "-") && len(arg) > 1:
 			// Short option combinations like -mc.
 			for _, r := range arg[1:] {
 				switch r {
 				case 'h':
-					help = true
+					opts.help = true
 				case 'm':
-					markdown = true
+					opts.markdown = true
 				case 'c':
-					copyOut = true
+					opts.copyOut = true
+				case 'w':
+					opts.watch = true
+				case 'f':
+					if i+1 >= len(args) {
+						return options{}, errors.New("missing --file value")
+					}
+					opts.file = stringFlag{value: args[i+1], set: true}
+					i++
 				case 'u':
 					if i+1 >= len(args) {
-						return false, false, false, stringFlag{}, nil, errors.New("missing URL value")
+						return options{}, errors.New("missing URL value")
 					}
-					urlArg = stringFlag{value: args[i+1], set: true}
+					opts.urlArg = stringFlag{value: args[i+1], set: true}
 					i++
 				default:
-					return false, false, false, stringFlag{}, nil, errors.New("unknown option")
+					return options{}, errors.New("unknown option")
 				}
 			}
 		default:
-			positional = append(positional, arg)
+			opts.positional = append(opts.positional, arg)
 		}
 	}
 
-	return help, markdown, copyOut, urlArg, positional, nil
+	return opts, nil
 }
 
 func resolveURL(positional []string, urlArg stringFlag) (string, error) {
@@ -157,92 +379,3 @@ func resolveURL(positional []string, urlArg stringFlag) (string, error) {
 		return "", errors.New("URLを指定してください")
 	}
 }
-
-func fetchTitle(rawURL string) (string, error) {
-	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
-		rawURL = "https://" + rawURL
-	}
-
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(rawURL)
-	if err != nil {
-		return "", fmt.Errorf("URLの取得に失敗しました: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf("HTTPエラー: %s", resp.Status)
-	}
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
-	if err != nil {
-		return "", fmt.Errorf("レスポンスの読み取りに失敗しました: %w", err)
-	}
-
-	match := titlePattern.FindSubmatch(body)
-	if len(match) < 2 {
-		return "", errors.New("titleタグが見つかりませんでした")
-	}
-
-	title := html.UnescapeString(string(match[1]))
-	title = strings.Join(strings.Fields(title), " ")
-	if title == "" {
-		return "", errors.New("titleが空でした")
-	}
-
-	return title, nil
-}
-
-func copyToClipboard(text string) error {
-	candidates := clipboardCommands()
-	for _, cmd := range candidates {
-		if err := pipeToCommand(text, cmd.name, cmd.args...); err == nil {
-			return nil
-		}
-	}
-	return errors.New("supported clipboard command not found (pbcopy/xclip/xsel/wl-copy/clip)")
-}
-
-type clipboardCmd struct {
-	name string
-	args []string
-}
-
-func clipboardCommands() []clipboardCmd {
-	switch runtime.GOOS {
-	case "darwin":
-		return []clipboardCmd{{name: "pbcopy"}}
-	case "windows":
-		return []clipboardCmd{{name: "clip"}}
-	default:
-		return []clipboardCmd{
-			{name: "wl-copy"},
-			{name: "xclip", args: []string{"-selection", "clipboard"}},
-			{name: "xsel", args: []string{"--clipboard", "--input"}},
-		}
-	}
-}
-
-func pipeToCommand(input, name string, args ...string) error {
-	if _, err := exec.LookPath(name); err != nil {
-		return err
-	}
-	cmd := exec.Command(name, args...)
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return err
-	}
-	if err := cmd.Start(); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(stdin, input); err != nil {
-		_ = stdin.Close()
-		_ = cmd.Wait()
-		return err
-	}
-	if err := stdin.Close(); err != nil {
-		_ = cmd.Wait()
-		return err
-	}
-	return cmd.Wait()
-}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultListen   = "127.0.0.1:4000"
+	defaultCacheTTL = 10 * time.Minute
+	defaultCacheCap = 200
+
+	// maxTitlesBodyBytes bounds how much of a POST /titles body we'll
+	// ever read, so an oversized payload can't be decoded into memory
+	// before the URL count below is even known.
+	maxTitlesBodyBytes = 1 << 20 // 1 MiB
+
+	// maxTitlesPerRequest bounds how many URLs a single POST /titles
+	// call can request at once.
+	maxTitlesPerRequest = 50
+
+	// titlesConcurrency bounds how many of those URLs are fetched
+	// concurrently, mirroring runBatch's --concurrency semaphore so
+	// this endpoint can't be used to spawn unbounded outbound fetches.
+	titlesConcurrency = 8
+)
+
+type serveOptions struct {
+	listen       string
+	corsOrigins  []string
+	titleSources []string
+	cacheTTL     time.Duration
+	cacheCap     int
+}
+
+func parseServeArgs(args []string) (serveOptions, error) {
+	opts := serveOptions{
+		listen:       defaultListen,
+		titleSources: defaultTitleSources,
+		cacheTTL:     defaultCacheTTL,
+		cacheCap:     defaultCacheCap,
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--listen":
+			if i+1 >= len(args) {
+				return serveOptions{}, errors.New("missing --listen value")
+			}
+			opts.listen = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--listen="):
+			opts.listen = strings.TrimPrefix(arg, "--listen=")
+		case arg == "--port":
+			if i+1 >= len(args) {
+				return serveOptions{}, errors.New("missing --port value")
+			}
+			port, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return serveOptions{}, fmt.Errorf("invalid --port value: %w", err)
+			}
+			opts.listen = replacePort(opts.listen, port)
+			i++
+		case strings.HasPrefix(arg, "--port="):
+			port, err := strconv.Atoi(strings.TrimPrefix(arg, "--port="))
+			if err != nil {
+				return serveOptions{}, fmt.Errorf("invalid --port value: %w", err)
+			}
+			opts.listen = replacePort(opts.listen, port)
+		case arg == "--cors-origin":
+			if i+1 >= len(args) {
+				return serveOptions{}, errors.New("missing --cors-origin value")
+			}
+			opts.corsOrigins = splitCommaList(args[i+1])
+			i++
+		case strings.HasPrefix(arg, "--cors-origin="):
+			opts.corsOrigins = splitCommaList(strings.TrimPrefix(arg, "--cors-origin="))
+		case arg == "--title-source":
+			if i+1 >= len(args) {
+				return serveOptions{}, errors.New("missing --title-source value")
+			}
+			sources, err := parseTitleSources(args[i+1])
+			if err != nil {
+				return serveOptions{}, err
+			}
+			opts.titleSources = sources
+			i++
+		case strings.HasPrefix(arg, "--title-source="):
+			sources, err := parseTitleSources(strings.TrimPrefix(arg, "--title-source="))
+			if err != nil {
+				return serveOptions{}, err
+			}
+			opts.titleSources = sources
+		case arg == "--cache-ttl":
+			if i+1 >= len(args) {
+				return serveOptions{}, errors.New("missing --cache-ttl value")
+			}
+			d, err := time.ParseDuration(args[i+1])
+			if err != nil {
+				return serveOptions{}, fmt.Errorf("invalid --cache-ttl value: %w", err)
+			}
+			opts.cacheTTL = d
+			i++
+		case strings.HasPrefix(arg, "--cache-ttl="):
+			d, err := time.ParseDuration(strings.TrimPrefix(arg, "--cache-ttl="))
+			if err != nil {
+				return serveOptions{}, fmt.Errorf("invalid --cache-ttl value: %w", err)
+			}
+			opts.cacheTTL = d
+		case arg == "-h" || arg == "--help":
+			return serveOptions{}, errHelpRequested
+		default:
+			return serveOptions{}, fmt.Errorf("unknown serve option %q", arg)
+		}
+	}
+
+	return opts, nil
+}
+
+var errHelpRequested = errors.New("help requested")
+
+func replacePort(listen string, port int) string {
+	host := listen
+	if idx := strings.LastIndex(listen, ":"); idx >= 0 {
+		host = listen[:idx]
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+func splitCommaList(raw string) []string {
+	parts := strings.Split(raw, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// runServe parses serve-specific flags and starts the HTTP server,
+// blocking until it fails to serve.
+func runServe(args []string, stdout, stderr io.Writer) int {
+	opts, err := parseServeArgs(args)
+	if err != nil {
+		if errors.Is(err, errHelpRequested) {
+			fmt.Fprint(stdout, usageText)
+			return 0
+		}
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	cache := newTitleCache(opts.cacheCap, opts.cacheTTL)
+	handler := newServeMux(opts, cache)
+
+	fmt.Fprintf(stdout, "ght serve listening on http://%s\n", opts.listen)
+	if err := http.ListenAndServe(opts.listen, handler); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+type titleResponse struct {
+	URL      string `json:"url"`
+	Title    string `json:"title,omitempty"`
+	Markdown string `json:"markdown,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func newServeMux(opts serveOptions, cache *titleCache) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/title", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		url := r.URL.Query().Get("url")
+		if strings.TrimSpace(url) == "" {
+			http.Error(w, "missing url query parameter", http.StatusBadRequest)
+			return
+		}
+		resp := lookupTitle(r.Context(), cache, opts.titleSources, url)
+		writeJSON(w, http.StatusOK, resp)
+	})
+
+	mux.HandleFunc("/titles", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var urls []string
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxTitlesBodyBytes)).Decode(&urls); err != nil {
+			http.Error(w, "invalid JSON body: expected an array of URLs", http.StatusBadRequest)
+			return
+		}
+		if len(urls) > maxTitlesPerRequest {
+			http.Error(w, fmt.Sprintf("too many URLs: got %d, max %d per request", len(urls), maxTitlesPerRequest), http.StatusBadRequest)
+			return
+		}
+
+		responses := make([]titleResponse, len(urls))
+		sem := make(chan struct{}, titlesConcurrency)
+		var wg sync.WaitGroup
+		for i, url := range urls {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, url string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				responses[i] = lookupTitle(r.Context(), cache, opts.titleSources, url)
+			}(i, url)
+		}
+		wg.Wait()
+
+		writeJSON(w, http.StatusOK, responses)
+	})
+
+	return withCORS(opts.corsOrigins, mux)
+}
+
+func lookupTitle(ctx context.Context, cache *titleCache, sources []string, url string) titleResponse {
+	// Key the cache on the same normalized form fetchTitle fetches under,
+	// so "example.com" and "https://example.com" share one cache entry
+	// instead of the raw query string splitting them in two.
+	canon := normalizeURL(url)
+	if title, ok := cache.Get(canon); ok {
+		return titleResponse{URL: canon, Title: title, Markdown: fmt.Sprintf("[%s](%s)", title, canon)}
+	}
+
+	result := fetchTitle(ctx, url, sources)
+	if result.Err != nil {
+		return titleResponse{URL: url, Error: result.Err.Error()}
+	}
+
+	cache.Set(canon, result.Title)
+	return titleResponse{
+		URL:      result.URL,
+		Title:    result.Title,
+		Markdown: fmt.Sprintf("[%s](%s)", result.Title, result.URL),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// withCORS adds CORS headers for the configured origin allow-list. An
+// empty list disables CORS entirely (same-origin only); "*" allows any
+// origin.
+func withCORS(allowed []string, next http.Handler) http.Handler {
+	if len(allowed) == 0 {
+		return next
+	}
+
+	allowAll := false
+	allowSet := make(map[string]bool, len(allowed))
+	for _, o := range allowed {
+		if o == "*" {
+			allowAll = true
+		}
+		allowSet[o] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && (allowAll || allowSet[origin]) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Vary", "Origin")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		}
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
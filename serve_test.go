@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTitlesHandlerRejectsTooManyURLs(t *testing.T) {
+	opts := serveOptions{titleSources: defaultTitleSources}
+	cache := newTitleCache(defaultCacheCap, time.Minute)
+	mux := newServeMux(opts, cache)
+
+	urls := make([]string, maxTitlesPerRequest+1)
+	for i := range urls {
+		urls[i] = "https://example.com/"
+	}
+	body, err := json.Marshal(urls)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/titles", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != 400 {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestTitlesHandlerRejectsOversizedBody(t *testing.T) {
+	opts := serveOptions{titleSources: defaultTitleSources}
+	cache := newTitleCache(defaultCacheCap, time.Minute)
+	mux := newServeMux(opts, cache)
+
+	oversized := bytes.Repeat([]byte("a"), maxTitlesBodyBytes+1)
+	body := append([]byte(`["https://example.com/", "`), oversized...)
+	body = append(body, []byte(`"]`)...)
+
+	req := httptest.NewRequest("POST", "/titles", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != 400 {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
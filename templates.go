@@ -0,0 +1,134 @@
+package main
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+)
+
+// resultTemplate is satisfied by both text/template.Template and
+// html/template.Template, letting resolveTemplate hand back whichever
+// one a format needs while renderResult stays agnostic to which.
+type resultTemplate interface {
+	Execute(wr io.Writer, data any) error
+}
+
+// templateFuncs are available to every custom template and to every
+// built-in template except "html-card", which is parsed with
+// html/template and gets no extra functions.
+// yamlQuote renders a field as a properly escaped YAML double-quoted
+// scalar, so title/description text containing a literal `"` or `\`
+// can't break out of the frontmatter block it's embedded in.
+var templateFuncs = texttemplate.FuncMap{
+	"yamlQuote": yamlQuote,
+}
+
+// builtinTemplates maps --format names to a text/template body rendered
+// against a fetchResult. "html-card" is rendered through html/template
+// instead (see resolveTemplate) so that fetched page content can't
+// inject markup into the card. The batch-only json/csv/tsv formats are
+// handled separately since they aren't simple text expansions.
+var builtinTemplates = map[string]string{
+	"plain":    `{{.Title}}`,
+	"markdown": `[{{.Title}}]({{.URL}})`,
+	"org":      `[[{{.URL}}][{{.Title}}]]`,
+	"hugo-frontmatter": `---
+title: {{yamlQuote .Title}}
+source: {{yamlQuote .URL}}
+{{- if .Description}}
+description: {{yamlQuote .Description}}
+{{- end}}
+{{- if .SiteName}}
+site_name: {{yamlQuote .SiteName}}
+{{- end}}
+{{- if .Author}}
+author: {{yamlQuote .Author}}
+{{- end}}
+{{- if .PublishedAt}}
+date: {{yamlQuote .PublishedAt}}
+{{- end}}
+{{- if .Image}}
+image: {{yamlQuote .Image}}
+{{- end}}
+---`,
+}
+
+// htmlCardTemplate is the "html-card" format's body. It's parsed with
+// html/template rather than builtinTemplates' text/template so that a
+// fetched page's title/description can never inject markup into the
+// card; html/template escapes each field per the HTML context it lands
+// in (text content vs. attribute value) automatically.
+const htmlCardTemplate = `<a class="ght-card" href="{{.URL}}">
+{{- if .Image}}
+  <img src="{{.Image}}" alt="">
+{{- end}}
+  <strong>{{.Title}}</strong>
+{{- if .Description}}
+  <p>{{.Description}}</p>
+{{- end}}
+{{- if .SiteName}}
+  <cite>{{.SiteName}}</cite>
+{{- end}}
+</a>`
+
+// yamlQuote escapes s for use as a YAML double-quoted scalar, including
+// the surrounding quotes.
+func yamlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// parseResultTemplate parses a user-supplied --template body, the same
+// text/template syntax text/template itself accepts, into a reusable
+// template rendering against a fetchResult.
+func parseResultTemplate(body string) (*texttemplate.Template, error) {
+	return texttemplate.New("ght-template").Funcs(templateFuncs).Parse(body)
+}
+
+// resolveTemplate picks the template to render a fetchResult with:
+// custom (from --template) if given, otherwise the built-in template
+// named by format, falling back to the bare title if format is itself
+// unset or unrecognized (json/csv/tsv are handled separately by their
+// own encoders and never reach here).
+func resolveTemplate(format, custom string) (resultTemplate, error) {
+	if custom != "" {
+		return parseResultTemplate(custom)
+	}
+	if format == "html-card" {
+		return htmltemplate.New("ght-html-card").Parse(htmlCardTemplate)
+	}
+	body, ok := builtinTemplates[format]
+	if !ok {
+		body = builtinTemplates["plain"]
+	}
+	return parseResultTemplate(body)
+}
+
+// renderResult expands tmpl (either a user-supplied --template or one
+// of builtinTemplates/htmlCardTemplate) against r, returning the
+// rendered text with trailing newlines trimmed so callers can append
+// their own newline.
+func renderResult(tmpl resultTemplate, r fetchResult) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, r); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveTemplateBuiltinFormats(t *testing.T) {
+	r := fetchResult{
+		URL:         "https://example.com/",
+		Title:       "Example Title",
+		Description: "An example page.",
+		SiteName:    "Example Site",
+	}
+
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{"plain", "Example Title"},
+		{"markdown", "[Example Title](https://example.com/)"},
+		{"org", "[[https://example.com/][Example Title]]"},
+	}
+
+	for _, c := range cases {
+		tmpl, err := resolveTemplate(c.format, "")
+		if err != nil {
+			t.Fatalf("resolveTemplate(%q): %v", c.format, err)
+		}
+		got, err := renderResult(tmpl, r)
+		if err != nil {
+			t.Fatalf("renderResult(%q): %v", c.format, err)
+		}
+		if got != c.want {
+			t.Errorf("format %q = %q, want %q", c.format, got, c.want)
+		}
+	}
+}
+
+func TestResolveTemplateHugoFrontmatterIncludesOptionalFields(t *testing.T) {
+	r := fetchResult{URL: "https://example.com/", Title: "Example Title", Description: "An example page."}
+
+	tmpl, err := resolveTemplate("hugo-frontmatter", "")
+	if err != nil {
+		t.Fatalf("resolveTemplate: %v", err)
+	}
+	got, err := renderResult(tmpl, r)
+	if err != nil {
+		t.Fatalf("renderResult: %v", err)
+	}
+	if !strings.Contains(got, `description: "An example page."`) {
+		t.Errorf("hugo-frontmatter output missing description field: %s", got)
+	}
+	if strings.Contains(got, "author:") {
+		t.Errorf("hugo-frontmatter output should omit unset author field: %s", got)
+	}
+}
+
+func TestResolveTemplateCustomOverridesFormat(t *testing.T) {
+	r := fetchResult{URL: "https://example.com/", Title: "Example Title"}
+
+	tmpl, err := resolveTemplate("markdown", "{{.Title}} <{{.URL}}>")
+	if err != nil {
+		t.Fatalf("resolveTemplate: %v", err)
+	}
+	got, err := renderResult(tmpl, r)
+	if err != nil {
+		t.Fatalf("renderResult: %v", err)
+	}
+	want := "Example Title <https://example.com/>"
+	if got != want {
+		t.Errorf("custom template output = %q, want %q", got, want)
+	}
+}
+
+func TestResolveTemplateUnknownFormatFallsBackToPlain(t *testing.T) {
+	r := fetchResult{Title: "Example Title"}
+
+	tmpl, err := resolveTemplate("", "")
+	if err != nil {
+		t.Fatalf("resolveTemplate: %v", err)
+	}
+	got, err := renderResult(tmpl, r)
+	if err != nil {
+		t.Fatalf("renderResult: %v", err)
+	}
+	if got != "Example Title" {
+		t.Errorf("default format output = %q, want %q", got, "Example Title")
+	}
+}
+
+func TestResolveTemplateHTMLCardEscapesPageContent(t *testing.T) {
+	r := fetchResult{
+		URL:   "https://example.com/",
+		Title: `Evil "Quote" <script>alert(1)</script> Title`,
+	}
+
+	tmpl, err := resolveTemplate("html-card", "")
+	if err != nil {
+		t.Fatalf("resolveTemplate: %v", err)
+	}
+	got, err := renderResult(tmpl, r)
+	if err != nil {
+		t.Fatalf("renderResult: %v", err)
+	}
+	if strings.Contains(got, "<script>") {
+		t.Errorf("html-card output contains an unescaped <script> tag: %s", got)
+	}
+}
+
+func TestResolveTemplateHugoFrontmatterEscapesQuotesAndBackslashes(t *testing.T) {
+	r := fetchResult{
+		URL:   "https://example.com/",
+		Title: `Evil "Quote" \ Title`,
+	}
+
+	tmpl, err := resolveTemplate("hugo-frontmatter", "")
+	if err != nil {
+		t.Fatalf("resolveTemplate: %v", err)
+	}
+	got, err := renderResult(tmpl, r)
+	if err != nil {
+		t.Fatalf("renderResult: %v", err)
+	}
+	want := `title: "Evil \"Quote\" \\ Title"`
+	if !strings.Contains(got, want) {
+		t.Errorf("hugo-frontmatter output = %q, want it to contain %q", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runWatch polls the clipboard at opts.watchInterval and, whenever its
+// contents change and match opts.watchPattern, replaces them with the
+// fetched page title (or its markdown link form, when opts.markdown is
+// set). It runs until interrupted (SIGINT/SIGTERM).
+func runWatch(opts options, stdout, stderr io.Writer) int {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	ticker := time.NewTicker(opts.watchInterval)
+	defer ticker.Stop()
+
+	var last string
+	for {
+		select {
+		case <-sigCh:
+			fmt.Fprintln(stdout, "watch mode stopped")
+			return 0
+		case <-ticker.C:
+			current, err := readClipboard(opts.noClipboardNative)
+			if err != nil {
+				fmt.Fprintf(stderr, "clipboard read failed: %v\n", err)
+				continue
+			}
+			if current == last {
+				continue
+			}
+			last = current
+
+			url := strings.TrimSpace(current)
+			if !opts.watchPattern.MatchString(url) {
+				continue
+			}
+
+			res := fetchTitle(context.Background(), url, opts.titleSources)
+			if res.Err != nil {
+				fmt.Fprintf(stderr, "%s: %v\n", url, res.Err)
+				continue
+			}
+
+			output := res.Title
+			if opts.markdown {
+				output = fmt.Sprintf("[%s](%s)", res.Title, res.URL)
+			}
+
+			if err := copyToClipboard(output, opts.noClipboardNative); err != nil {
+				fmt.Fprintf(stderr, "clipboard copy failed: %v\n", err)
+				continue
+			}
+			last = output
+			fmt.Fprintln(stdout, output)
+		}
+	}
+}